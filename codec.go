@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+	"github.com/SkippyHub/huffmanlz77compression/pkg/huffman"
+)
+
+// bytesToRuneString maps each byte of data to its own rune. Every byte value
+// 0-255 is a valid (if occasionally multi-byte) UTF-8 scalar value, so this
+// lets preprocess.Preprocessor implementations -- which only promise to be
+// safe on raw byte streams -- compose with the rune-oriented Huffman stage
+// without distinct byte values collapsing onto the same invalid-rune
+// replacement character.
+func bytesToRuneString(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// runeStringToBytes is the inverse of bytesToRuneString.
+func runeStringToBytes(s string) []byte {
+	runes := []rune(s)
+	out := make([]byte, len(runes))
+	for i, r := range runes {
+		out[i] = byte(r)
+	}
+	return out
+}
+
+// EncodeTree writes enc's canonical code lengths as a compact header: a
+// count, then for each symbol its rune and code length. DecodeTree rebuilds
+// an identical canonical assignment from just those lengths, the same trick
+// DEFLATE's dynamic blocks use to avoid ever serializing the tree shape
+// itself.
+func EncodeTree(w io.Writer, enc *huffman.Encoder[rune]) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(enc.Codes))); err != nil {
+		return err
+	}
+	for sym, code := range enc.Codes {
+		if err := binary.Write(w, binary.BigEndian, int32(sym)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint8(code.Len)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeTree reads a header written by EncodeTree and rebuilds the canonical
+// encoder it described.
+func DecodeTree(r io.Reader) (*huffman.Encoder[rune], error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	lengths := make(map[rune]int, count)
+	for i := uint32(0); i < count; i++ {
+		var sym int32
+		var length uint8
+		if err := binary.Read(r, binary.BigEndian, &sym); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		lengths[rune(sym)] = int(length)
+	}
+	return huffman.FromLengths(lengths), nil
+}
+
+// encodeByteTree writes enc's canonical code lengths as a compact header,
+// the same wire format as EncodeTree but keyed by raw byte values rather
+// than runes.
+func encodeByteTree(w io.Writer, enc *huffman.Encoder[byte]) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(enc.Codes))); err != nil {
+		return err
+	}
+	for sym, code := range enc.Codes {
+		if err := binary.Write(w, binary.BigEndian, sym); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint8(code.Len)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeByteTree reads a header written by encodeByteTree and rebuilds the
+// canonical encoder it described.
+func decodeByteTree(r io.Reader) (*huffman.Encoder[byte], error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	lengths := make(map[byte]int, count)
+	for i := uint32(0); i < count; i++ {
+		var sym byte
+		var length uint8
+		if err := binary.Read(r, binary.BigEndian, &sym); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		lengths[sym] = int(length)
+	}
+	return huffman.FromLengths(lengths), nil
+}
+
+// EncodeFile Huffman-encodes the file at in and writes a self-contained
+// artifact to out: a codebook header, the exact bit count, then the packed
+// bits. It builds the Huffman alphabet over the file's raw bytes rather than
+// decoding them as UTF-8 text, so arbitrary (including non-UTF-8) files round
+// trip exactly; the result needs no external codebook to decode.
+func EncodeFile(in, out string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var freqs []huffman.Freq[byte]
+	for sym, count := range counts {
+		if count > 0 {
+			freqs = append(freqs, huffman.Freq[byte]{Symbol: byte(sym), Count: count})
+		}
+	}
+	enc := huffman.Build(freqs)
+
+	var payload bytes.Buffer
+	bw := bitio.NewWriter(&payload)
+	nbits := 0
+	for _, b := range data {
+		code := enc.Codes[b]
+		if err := huffman.Write(bw, code); err != nil {
+			return err
+		}
+		nbits += int(code.Len)
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fw := bufio.NewWriter(f)
+	if err := encodeByteTree(fw, enc); err != nil {
+		return err
+	}
+	if err := binary.Write(fw, binary.BigEndian, uint64(nbits)); err != nil {
+		return err
+	}
+	if _, err := fw.Write(payload.Bytes()); err != nil {
+		return err
+	}
+	return fw.Flush()
+}
+
+// DecodeFile reads an artifact written by EncodeFile and writes the
+// reconstructed bytes to out.
+func DecodeFile(in, out string) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	enc, err := decodeByteTree(br)
+	if err != nil {
+		return err
+	}
+
+	var nbits uint64
+	if err := binary.Read(br, binary.BigEndian, &nbits); err != nil {
+		return err
+	}
+	payload, err := io.ReadAll(br)
+	if err != nil {
+		return err
+	}
+
+	dec := huffman.NewDecoder(enc)
+	bitReader := bitio.NewReader(bytes.NewReader(payload))
+	var decoded []byte
+	read := 0
+	for read < int(nbits) {
+		sym, n, err := dec.Decode(bitReader)
+		if err != nil {
+			return err
+		}
+		decoded = append(decoded, sym)
+		read += n
+	}
+	return os.WriteFile(out, decoded, 0o644)
+}
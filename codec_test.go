@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeDecodeFileRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"single byte", []byte("x")},
+		{"non-ASCII", []byte("héllo wörld — こんにちは 👋")},
+		{"non-UTF-8 binary", invalidUTF8Corpus()},
+	}
+
+	dir := t.TempDir()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in := filepath.Join(dir, "in")
+			encoded := filepath.Join(dir, "encoded.huff")
+			decoded := filepath.Join(dir, "decoded")
+
+			if err := os.WriteFile(in, c.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := EncodeFile(in, encoded); err != nil {
+				t.Fatalf("EncodeFile: %v", err)
+			}
+			if err := DecodeFile(encoded, decoded); err != nil {
+				t.Fatalf("DecodeFile: %v", err)
+			}
+
+			got, err := os.ReadFile(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, c.data) {
+				t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", len(got), len(c.data))
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeFileRoundTripSampleHTML(t *testing.T) {
+	sample, err := os.ReadFile("sample.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	encoded := filepath.Join(dir, "sample.huff")
+	decoded := filepath.Join(dir, "sample.decoded")
+
+	if err := EncodeFile("sample.html", encoded); err != nil {
+		t.Fatalf("EncodeFile: %v", err)
+	}
+	if err := DecodeFile(encoded, decoded); err != nil {
+		t.Fatalf("DecodeFile: %v", err)
+	}
+
+	got, err := os.ReadFile(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, sample) {
+		t.Fatalf("sample.html round trip mismatch: got %d bytes, want %d bytes", len(got), len(sample))
+	}
+}
+
+// invalidUTF8Corpus returns bytes spanning every value 0-255, including
+// lone continuation and lead bytes that cannot decode as valid UTF-8 --
+// the case that once corrupted EncodeFile/DecodeFile's rune-based pipeline.
+func invalidUTF8Corpus() []byte {
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return data
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+	"github.com/SkippyHub/huffmanlz77compression/pkg/huffman"
+)
+
+// buildEncoding computes a canonical, length-limited Huffman code over the
+// given rune frequency table using the generic pkg/huffman engine -- the
+// same engine pkg/deflatelike uses for its literal/length and distance
+// alphabets.
+func buildEncoding(leaves []HuffmanLeaf) *huffman.Encoder[rune] {
+	freqs := make([]huffman.Freq[rune], len(leaves))
+	for i, l := range leaves {
+		freqs[i] = huffman.Freq[rune]{Symbol: l.Value, Count: l.Frequency}
+	}
+	return huffman.Build(freqs)
+}
+
+// applyHuffmanEncoding packs s through enc's canonical codes and returns the
+// packed bytes along with the exact number of bits written, since the last
+// byte is generally zero-padded.
+func applyHuffmanEncoding(s string, enc *huffman.Encoder[rune]) ([]byte, int, error) {
+	var buf bytes.Buffer
+	bw := bitio.NewWriter(&buf)
+	nbits := 0
+	for _, c := range s {
+		code, ok := enc.Codes[c]
+		if !ok {
+			return nil, 0, fmt.Errorf("huffman: no code for symbol %q", c)
+		}
+		if err := huffman.Write(bw, code); err != nil {
+			return nil, 0, err
+		}
+		nbits += int(code.Len)
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), nbits, nil
+}
+
+// applyHuffmanDecoding unpacks bitLen bits of data using enc's codes.
+func applyHuffmanDecoding(data []byte, bitLen int, enc *huffman.Encoder[rune]) (string, error) {
+	dec := huffman.NewDecoder(enc)
+	br := bitio.NewReader(bytes.NewReader(data))
+	var out []rune
+	read := 0
+	for read < bitLen {
+		sym, n, err := dec.Decode(br)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, sym)
+		read += n
+	}
+	return string(out), nil
+}
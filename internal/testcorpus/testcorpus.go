@@ -0,0 +1,23 @@
+// Package testcorpus generates deterministic, compressible multi-megabyte
+// test data shared by pkg/lz77 and pkg/deflatelike's tests, standing in for
+// the kind of HTML/text input this repository's compressor is tuned for.
+package testcorpus
+
+import (
+	"bytes"
+	"math/rand"
+)
+
+// Generate returns size bytes of a small vocabulary of words repeated in
+// random order, built from a fixed seed so results are reproducible across
+// runs.
+func Generate(size int) []byte {
+	words := []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog", "<div>", "</div>", "class=\"row\""}
+	r := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	for buf.Len() < size {
+		buf.WriteString(words[r.Intn(len(words))])
+		buf.WriteByte(' ')
+	}
+	return buf.Bytes()[:size]
+}
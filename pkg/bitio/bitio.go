@@ -0,0 +1,132 @@
+// Package bitio packs individual bits into bytes and back, most-significant
+// bit first within each byte -- the convention DEFLATE uses for Huffman
+// codes. It underlies both the standalone Huffman coder in the root package
+// and the pkg/deflatelike block format.
+package bitio
+
+import "io"
+
+// Writer packs individual bits into bytes and flushes them to an underlying
+// io.Writer.
+type Writer struct {
+	w       io.Writer
+	cur     byte
+	nbits   uint
+	scratch [1]byte
+}
+
+// NewWriter returns a Writer that writes packed bits to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteBit writes a single bit (0 or nonzero) to the stream.
+func (bw *Writer) WriteBit(bit int) error {
+	bw.cur <<= 1
+	if bit != 0 {
+		bw.cur |= 1
+	}
+	bw.nbits++
+	if bw.nbits == 8 {
+		return bw.flushByte()
+	}
+	return nil
+}
+
+// WriteBits writes the low nbits bits of value, most-significant bit first.
+func (bw *Writer) WriteBits(value uint32, nbits int) error {
+	for i := nbits - 1; i >= 0; i-- {
+		if err := bw.WriteBit(int((value >> uint(i)) & 1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bw *Writer) flushByte() error {
+	bw.scratch[0] = bw.cur
+	if _, err := bw.w.Write(bw.scratch[:]); err != nil {
+		return err
+	}
+	bw.cur = 0
+	bw.nbits = 0
+	return nil
+}
+
+// Flush pads any partial byte with zero bits and writes it out. It is a
+// no-op if the stream already ends on a byte boundary.
+func (bw *Writer) Flush() error {
+	if bw.nbits == 0 {
+		return nil
+	}
+	bw.cur <<= 8 - bw.nbits
+	bw.nbits = 8
+	return bw.flushByte()
+}
+
+// Reader reads individual bits, most-significant-bit first, from an
+// underlying io.Reader.
+type Reader struct {
+	r       io.Reader
+	buf     uint32
+	nbits   uint
+	scratch [1]byte
+}
+
+// NewReader returns a Reader that reads packed bits from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+func (br *Reader) fill(nbits int) error {
+	for int(br.nbits) < nbits {
+		if _, err := io.ReadFull(br.r, br.scratch[:]); err != nil {
+			return err
+		}
+		br.buf = br.buf<<8 | uint32(br.scratch[0])
+		br.nbits += 8
+	}
+	return nil
+}
+
+// PeekBits returns the next nbits bits without consuming them.
+func (br *Reader) PeekBits(nbits int) (uint32, error) {
+	if err := br.fill(nbits); err != nil {
+		return 0, err
+	}
+	return (br.buf >> (br.nbits - uint(nbits))) & ((1 << uint(nbits)) - 1), nil
+}
+
+// ConsumeBits drops the next nbits bits, previously returned by PeekBits.
+func (br *Reader) ConsumeBits(nbits int) {
+	br.nbits -= uint(nbits)
+	br.buf &= (1 << br.nbits) - 1
+}
+
+// ReadBits reads and consumes the next nbits bits, most-significant bit first.
+func (br *Reader) ReadBits(nbits int) (uint32, error) {
+	v, err := br.PeekBits(nbits)
+	if err != nil {
+		return 0, err
+	}
+	br.ConsumeBits(nbits)
+	return v, nil
+}
+
+// ReadBit reads and consumes a single bit.
+func (br *Reader) ReadBit() (int, error) {
+	v, err := br.ReadBits(1)
+	if err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
+
+// Align discards any bits left over from a partially consumed byte, so the
+// next read starts at a fresh byte boundary -- the padding DEFLATE inserts
+// before a stored block.
+func (br *Reader) Align() {
+	drop := br.nbits % 8
+	br.nbits -= drop
+	br.buf &= (1 << br.nbits) - 1
+}
@@ -0,0 +1,494 @@
+package deflatelike
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+	"github.com/SkippyHub/huffmanlz77compression/pkg/huffman"
+	"github.com/SkippyHub/huffmanlz77compression/pkg/lz77"
+)
+
+// minMatchLength is the shortest match the length-code table can represent;
+// a shorter LZ77 match is emitted as literal bytes instead.
+const minMatchLength = 3
+
+// codeLengthAlphabet is the size of the auxiliary alphabet (0-18) used to
+// RLE-compress the litlen/dist code lengths in a dynamic block's header.
+const codeLengthAlphabet = 19
+
+type blockType int
+
+const (
+	blockStored blockType = iota
+	blockFixed
+	blockDynamic
+)
+
+// buildAlphabetEncoder builds a canonical Huffman encoder over a fixed-size
+// alphabet from a frequency table indexed by symbol, and also returns the
+// resulting per-symbol code lengths (0 for symbols that never occurred).
+func buildAlphabetEncoder(freq []int, alphabetSize int) (*huffman.Encoder[int], []int) {
+	var freqs []huffman.Freq[int]
+	for sym, c := range freq {
+		if c > 0 {
+			freqs = append(freqs, huffman.Freq[int]{Symbol: sym, Count: c})
+		}
+	}
+	lengths := make([]int, alphabetSize)
+	if len(freqs) == 0 {
+		return &huffman.Encoder[int]{Codes: map[int]huffman.Code{}}, lengths
+	}
+	enc := huffman.Build(freqs)
+	for sym, c := range enc.Codes {
+		lengths[sym] = int(c.Len)
+	}
+	return enc, lengths
+}
+
+// splitMatchLength breaks an LZ77 match length into one or more chunks no
+// longer than maxMatchLength (the longest length a single length code can
+// represent), leaving any final remainder shorter than minMatchLength to be
+// emitted as literal bytes instead.
+func splitMatchLength(length int) (matchLens []int, literalTail int) {
+	remaining := length
+	for remaining >= minMatchLength {
+		take := remaining
+		if take > maxMatchLength {
+			take = maxMatchLength
+		}
+		matchLens = append(matchLens, take)
+		remaining -= take
+	}
+	return matchLens, remaining
+}
+
+func lengthsMapFromArray(lengths []int) map[int]int {
+	m := make(map[int]int, len(lengths))
+	for sym, l := range lengths {
+		if l > 0 {
+			m[sym] = l
+		}
+	}
+	return m
+}
+
+// collectFrequencies walks chunk's LZ77 tokens and tallies how often each
+// literal/length and distance symbol would be used to encode it.
+func collectFrequencies(chunk []byte, tokens []lz77.Token) (litlenFreq, distFreq []int) {
+	litlenFreq = make([]int, litlenAlphabet)
+	distFreq = make([]int, distAlphabet)
+	litlenFreq[endOfBlockSymbol]++
+
+	pos := 0
+	for _, t := range tokens {
+		if t.Length >= minMatchLength {
+			matchLens, tail := splitMatchLength(t.Length)
+			dsym, _, _ := distanceToSymbol(t.Distance)
+			for _, l := range matchLens {
+				sym, _, _ := lengthToSymbol(l)
+				litlenFreq[sym]++
+				distFreq[dsym]++
+			}
+			consumed := t.Length - tail
+			for k := 0; k < tail; k++ {
+				litlenFreq[int(chunk[pos+consumed+k])]++
+			}
+			pos += t.Length
+		} else {
+			for k := 0; k < t.Length; k++ {
+				litlenFreq[int(chunk[pos+k])]++
+			}
+			pos += t.Length
+		}
+		if pos < len(chunk) {
+			litlenFreq[int(chunk[pos])]++
+			pos++
+		}
+	}
+	return litlenFreq, distFreq
+}
+
+// fixedBlockBits estimates the token-stream cost of a block under DEFLATE's
+// predefined fixed Huffman tables.
+func fixedBlockBits(litlenFreq, distFreq []int) int {
+	litlenLens := fixedLitLenLengths()
+	distLens := fixedDistLengths()
+	bits := 0
+	for sym, f := range litlenFreq {
+		bits += f * litlenLens[sym]
+	}
+	for sym, f := range distFreq {
+		bits += f * distLens[sym]
+	}
+	return bits
+}
+
+// dynamicBlockBits estimates the total cost (header plus token stream) of
+// encoding a block with custom per-block Huffman tables.
+func dynamicBlockBits(litlenFreq, distFreq []int) int {
+	_, litlenLengths := buildAlphabetEncoder(litlenFreq, litlenAlphabet)
+	_, distLengths := buildAlphabetEncoder(distFreq, distAlphabet)
+
+	bits := 0
+	for sym, f := range litlenFreq {
+		bits += f * litlenLengths[sym]
+	}
+	for sym, f := range distFreq {
+		bits += f * distLengths[sym]
+	}
+
+	allLengths := append(append([]int{}, litlenLengths...), distLengths...)
+	clTokens := encodeCodeLengths(allLengths)
+	bits += codeLengthAlphabet * 5 // the code-length-of-lengths header
+	bits += len(clTokens) * 8      // rough per-token header cost
+	return bits
+}
+
+// writeBlock encodes chunk as a single block, estimating the bit cost of
+// each representation (stored, fixed Huffman, dynamic Huffman) and emitting
+// whichever is cheapest.
+func writeBlock(bw *bitio.Writer, chunk []byte, windowSize int, final bool) error {
+	tokens := lz77.Compress(chunk, windowSize)
+	litlenFreq, distFreq := collectFrequencies(chunk, tokens)
+
+	storedBits := 3 + 32 + len(chunk)*8
+	fixedBits := 3 + fixedBlockBits(litlenFreq, distFreq)
+	dynamicBits := 3 + dynamicBlockBits(litlenFreq, distFreq)
+
+	bt, cost := blockDynamic, dynamicBits
+	if fixedBits < cost {
+		bt, cost = blockFixed, fixedBits
+	}
+	if storedBits < cost {
+		bt, cost = blockStored, storedBits
+	}
+	_ = cost
+
+	finalBit := 0
+	if final {
+		finalBit = 1
+	}
+	if err := bw.WriteBits(uint32(finalBit), 1); err != nil {
+		return err
+	}
+	if err := bw.WriteBits(uint32(bt), 2); err != nil {
+		return err
+	}
+
+	switch bt {
+	case blockStored:
+		return writeStoredBlock(bw, chunk)
+	case blockFixed:
+		litlenEnc := huffman.FromLengths(fixedLitLenLengths())
+		distEnc := huffman.FromLengths(fixedDistLengths())
+		return writeTokenStream(bw, litlenEnc, distEnc, chunk, tokens)
+	default:
+		return writeDynamicBlock(bw, chunk, tokens, litlenFreq, distFreq)
+	}
+}
+
+func writeStoredBlock(bw *bitio.Writer, chunk []byte) error {
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(chunk)))
+	for _, b := range lenBuf {
+		if err := bw.WriteBits(uint32(b), 8); err != nil {
+			return err
+		}
+	}
+	for _, b := range chunk {
+		if err := bw.WriteBits(uint32(b), 8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDynamicBlock(bw *bitio.Writer, chunk []byte, tokens []lz77.Token, litlenFreq, distFreq []int) error {
+	litlenEnc, litlenLengths := buildAlphabetEncoder(litlenFreq, litlenAlphabet)
+	distEnc, distLengths := buildAlphabetEncoder(distFreq, distAlphabet)
+
+	allLengths := append(append([]int{}, litlenLengths...), distLengths...)
+	clTokens := encodeCodeLengths(allLengths)
+
+	clFreq := make([]int, codeLengthAlphabet)
+	for _, t := range clTokens {
+		clFreq[t.sym]++
+	}
+	clEnc, clLengths := buildAlphabetEncoder(clFreq, codeLengthAlphabet)
+
+	for _, l := range clLengths {
+		if err := bw.WriteBits(uint32(l), 5); err != nil {
+			return err
+		}
+	}
+	for _, t := range clTokens {
+		code, ok := clEnc.Codes[t.sym]
+		if !ok {
+			return fmt.Errorf("deflatelike: missing code-length code for symbol %d", t.sym)
+		}
+		if err := huffman.Write(bw, code); err != nil {
+			return err
+		}
+		if t.extraBits > 0 {
+			if err := bw.WriteBits(uint32(t.extra), t.extraBits); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writeTokenStream(bw, litlenEnc, distEnc, chunk, tokens)
+}
+
+// writeTokenStream emits chunk's LZ77 tokens as literal and match symbols
+// under litlenEnc/distEnc, terminated by the end-of-block symbol.
+func writeTokenStream(bw *bitio.Writer, litlenEnc, distEnc *huffman.Encoder[int], chunk []byte, tokens []lz77.Token) error {
+	emitLiteral := func(b byte) error {
+		code, ok := litlenEnc.Codes[int(b)]
+		if !ok {
+			return fmt.Errorf("deflatelike: missing literal code for byte %d", b)
+		}
+		return huffman.Write(bw, code)
+	}
+
+	pos := 0
+	for _, t := range tokens {
+		if t.Length >= minMatchLength {
+			matchLens, tail := splitMatchLength(t.Length)
+			dsym, dExtraBits, dExtraVal := distanceToSymbol(t.Distance)
+			dcode, ok := distEnc.Codes[dsym]
+			if !ok {
+				return fmt.Errorf("deflatelike: missing distance code for symbol %d", dsym)
+			}
+			for _, l := range matchLens {
+				sym, extraBits, extraVal := lengthToSymbol(l)
+				code, ok := litlenEnc.Codes[sym]
+				if !ok {
+					return fmt.Errorf("deflatelike: missing length code for symbol %d", sym)
+				}
+				if err := huffman.Write(bw, code); err != nil {
+					return err
+				}
+				if extraBits > 0 {
+					if err := bw.WriteBits(uint32(extraVal), extraBits); err != nil {
+						return err
+					}
+				}
+				if err := huffman.Write(bw, dcode); err != nil {
+					return err
+				}
+				if dExtraBits > 0 {
+					if err := bw.WriteBits(uint32(dExtraVal), dExtraBits); err != nil {
+						return err
+					}
+				}
+			}
+			consumed := t.Length - tail
+			for k := 0; k < tail; k++ {
+				if err := emitLiteral(chunk[pos+consumed+k]); err != nil {
+					return err
+				}
+			}
+			pos += t.Length
+		} else {
+			for k := 0; k < t.Length; k++ {
+				if err := emitLiteral(chunk[pos+k]); err != nil {
+					return err
+				}
+			}
+			pos += t.Length
+		}
+		if pos < len(chunk) {
+			if err := emitLiteral(chunk[pos]); err != nil {
+				return err
+			}
+			pos++
+		}
+	}
+
+	eob, ok := litlenEnc.Codes[endOfBlockSymbol]
+	if !ok {
+		return fmt.Errorf("deflatelike: missing end-of-block code")
+	}
+	return huffman.Write(bw, eob)
+}
+
+// readBlock reads and decompresses a single block from br, appending its
+// decoded bytes to w, and reports whether this was the final block.
+func readBlock(br *bitio.Reader, w io.Writer) (final bool, err error) {
+	finalBit, err := br.ReadBits(1)
+	if err != nil {
+		return false, err
+	}
+	btBits, err := br.ReadBits(2)
+	if err != nil {
+		return false, err
+	}
+
+	switch blockType(btBits) {
+	case blockStored:
+		err = readStoredBlock(br, w)
+	case blockFixed:
+		litlenDec := huffman.NewDecoder(huffman.FromLengths(fixedLitLenLengths()))
+		distDec := huffman.NewDecoder(huffman.FromLengths(fixedDistLengths()))
+		err = readTokenStream(br, litlenDec, distDec, w)
+	case blockDynamic:
+		err = readDynamicBlock(br, w)
+	default:
+		err = fmt.Errorf("deflatelike: unknown block type %d", btBits)
+	}
+	return finalBit == 1, err
+}
+
+func readStoredBlock(br *bitio.Reader, w io.Writer) error {
+	br.Align()
+	var lenBuf [4]byte
+	for i := range lenBuf {
+		b, err := br.ReadBits(8)
+		if err != nil {
+			return err
+		}
+		lenBuf[i] = byte(b)
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	buf := make([]byte, n)
+	for i := range buf {
+		b, err := br.ReadBits(8)
+		if err != nil {
+			return err
+		}
+		buf[i] = byte(b)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readDynamicBlock(br *bitio.Reader, w io.Writer) error {
+	clLengths := make(map[int]int, codeLengthAlphabet)
+	for i := 0; i < codeLengthAlphabet; i++ {
+		v, err := br.ReadBits(5)
+		if err != nil {
+			return err
+		}
+		if v > 0 {
+			clLengths[i] = int(v)
+		}
+	}
+	clDec := huffman.NewDecoder(huffman.FromLengths(clLengths))
+
+	total := litlenAlphabet + distAlphabet
+	lengths := make([]int, 0, total)
+	for len(lengths) < total {
+		sym, _, err := clDec.Decode(br)
+		if err != nil {
+			return err
+		}
+		switch sym {
+		case 16:
+			if len(lengths) == 0 {
+				return fmt.Errorf("deflatelike: repeat code with no previous length")
+			}
+			v, err := br.ReadBits(2)
+			if err != nil {
+				return err
+			}
+			prev := lengths[len(lengths)-1]
+			for i := 0; i < int(v)+3; i++ {
+				lengths = append(lengths, prev)
+			}
+		case 17:
+			v, err := br.ReadBits(3)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < int(v)+3; i++ {
+				lengths = append(lengths, 0)
+			}
+		case 18:
+			v, err := br.ReadBits(7)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < int(v)+11; i++ {
+				lengths = append(lengths, 0)
+			}
+		default:
+			lengths = append(lengths, sym)
+		}
+	}
+	lengths = lengths[:total]
+
+	litlenDec := huffman.NewDecoder(huffman.FromLengths(lengthsMapFromArray(lengths[:litlenAlphabet])))
+	distDec := huffman.NewDecoder(huffman.FromLengths(lengthsMapFromArray(lengths[litlenAlphabet:])))
+	return readTokenStream(br, litlenDec, distDec, w)
+}
+
+// readTokenStream decodes literal and back-reference symbols until the
+// end-of-block marker, writing the reconstructed bytes to w.
+func readTokenStream(br *bitio.Reader, litlenDec, distDec *huffman.Decoder[int], w io.Writer) error {
+	var window []byte
+	for {
+		sym, _, err := litlenDec.Decode(br)
+		if err != nil {
+			return err
+		}
+		switch {
+		case sym == endOfBlockSymbol:
+			_, err := w.Write(window)
+			return err
+		case sym < endOfBlockSymbol:
+			window = append(window, byte(sym))
+		default:
+			length, err := readLength(br, sym)
+			if err != nil {
+				return err
+			}
+			dsym, _, err := distDec.Decode(br)
+			if err != nil {
+				return err
+			}
+			distance, err := readDistance(br, dsym)
+			if err != nil {
+				return err
+			}
+			start := len(window) - distance
+			if start < 0 {
+				return fmt.Errorf("deflatelike: invalid back-reference distance %d", distance)
+			}
+			for k := 0; k < length; k++ {
+				window = append(window, window[start+k])
+			}
+		}
+	}
+}
+
+func readLength(br *bitio.Reader, sym int) (int, error) {
+	idx := sym - firstLengthSymbol
+	extraBits := lengthExtraBits[idx]
+	extra := 0
+	if extraBits > 0 {
+		v, err := br.ReadBits(extraBits)
+		if err != nil {
+			return 0, err
+		}
+		extra = int(v)
+	}
+	return symbolToLength(sym, extra), nil
+}
+
+func readDistance(br *bitio.Reader, sym int) (int, error) {
+	extraBits := distExtraBits[sym]
+	extra := 0
+	if extraBits > 0 {
+		v, err := br.ReadBits(extraBits)
+		if err != nil {
+			return 0, err
+		}
+		extra = int(v)
+	}
+	return symbolToDistance(sym, extra), nil
+}
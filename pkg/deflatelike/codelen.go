@@ -0,0 +1,67 @@
+package deflatelike
+
+// clToken is one entry in the run-length-encoded code-length stream that
+// forms a dynamic block's header: either a literal length (0-15) or one of
+// the three DEFLATE repeat codes (16, 17, 18).
+type clToken struct {
+	sym       int
+	extra     int
+	extraBits int
+}
+
+// encodeCodeLengths run-length-encodes a sequence of code lengths the way
+// DEFLATE does for its dynamic Huffman header: code 16 repeats the previous
+// length 3-6 times, and 17/18 repeat a zero length 3-10 or 11-138 times.
+func encodeCodeLengths(lengths []int) []clToken {
+	var tokens []clToken
+	i, n := 0, len(lengths)
+	for i < n {
+		length := lengths[i]
+		run := 1
+		for i+run < n && lengths[i+run] == length {
+			run++
+		}
+		if length == 0 {
+			remaining := run
+			for remaining > 0 {
+				switch {
+				case remaining >= 11:
+					take := remaining
+					if take > 138 {
+						take = 138
+					}
+					tokens = append(tokens, clToken{sym: 18, extra: take - 11, extraBits: 7})
+					remaining -= take
+				case remaining >= 3:
+					take := remaining
+					if take > 10 {
+						take = 10
+					}
+					tokens = append(tokens, clToken{sym: 17, extra: take - 3, extraBits: 3})
+					remaining -= take
+				default:
+					tokens = append(tokens, clToken{sym: 0})
+					remaining--
+				}
+			}
+		} else {
+			tokens = append(tokens, clToken{sym: length})
+			remaining := run - 1
+			for remaining > 0 {
+				if remaining >= 3 {
+					take := remaining
+					if take > 6 {
+						take = 6
+					}
+					tokens = append(tokens, clToken{sym: 16, extra: take - 3, extraBits: 2})
+					remaining -= take
+				} else {
+					tokens = append(tokens, clToken{sym: length})
+					remaining--
+				}
+			}
+		}
+		i += run
+	}
+	return tokens
+}
@@ -0,0 +1,78 @@
+// Package deflatelike ties this repository's LZ77 matcher and canonical
+// Huffman coder together into a single DEFLATE-inspired compressor: each
+// block of input is LZ77-matched, its tokens are mapped onto a combined
+// literal/length alphabet and a distance alphabet, and those alphabets are
+// entropy-coded with per-block Huffman tables (RFC 1951 is the model, not a
+// compatibility target).
+package deflatelike
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+)
+
+// Options configures a Compress call.
+type Options struct {
+	// WindowSize bounds how far back LZ77 may look for a match.
+	WindowSize int
+	// BlockSize is the maximum number of input bytes encoded per block;
+	// each block independently picks stored/fixed/dynamic Huffman coding.
+	BlockSize int
+}
+
+// DefaultOptions mirrors a modest zlib-style configuration.
+var DefaultOptions = Options{WindowSize: 32768, BlockSize: 32768}
+
+// maxWindowSize is the largest match distance the distance alphabet
+// (distBase/distExtraBits in tables.go) can represent: distBase's last
+// entry (24577) plus its 13 extra bits (2^13-1).
+const maxWindowSize = 32768
+
+// Compress reads all of r, splits it into blocks, and writes the resulting
+// LZ77+Huffman bitstream to w.
+func Compress(r io.Reader, w io.Writer, opts Options) error {
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = DefaultOptions.WindowSize
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = DefaultOptions.BlockSize
+	}
+	if opts.WindowSize > maxWindowSize {
+		return fmt.Errorf("deflatelike: WindowSize %d exceeds the distance alphabet's maximum of %d", opts.WindowSize, maxWindowSize)
+	}
+
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	bw := bitio.NewWriter(w)
+	for start := 0; start == 0 || start < len(input); start += opts.BlockSize {
+		end := start + opts.BlockSize
+		if end > len(input) {
+			end = len(input)
+		}
+		final := end >= len(input)
+		if err := writeBlock(bw, input[start:end], opts.WindowSize, final); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Decompress reads a stream written by Compress from r and writes the
+// reconstructed bytes to w.
+func Decompress(r io.Reader, w io.Writer) error {
+	br := bitio.NewReader(r)
+	for {
+		final, err := readBlock(br, w)
+		if err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package deflatelike
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SkippyHub/huffmanlz77compression/internal/testcorpus"
+)
+
+func TestCompressDecompressRoundTrip(t *testing.T) {
+	sample, err := os.ReadFile(filepath.Join("..", "..", "sample.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		opts Options
+	}{
+		{"empty", nil, DefaultOptions},
+		{"short", []byte("hello"), DefaultOptions},
+		{"repeated", bytes.Repeat([]byte("abcabcabc"), 100), DefaultOptions},
+		{"sample.html", sample, DefaultOptions},
+		{"sample.html multi-block", sample, Options{WindowSize: DefaultOptions.WindowSize, BlockSize: 64}},
+		{"not repeating", []byte(strings.Repeat("xyzzy ", 1)), DefaultOptions},
+		// A multi-megabyte corpus, large and varied enough to give a block's
+		// litlen alphabet real diversity (literal bytes plus a wide spread of
+		// match lengths/distances) -- sample.html alone is too small/uniform
+		// to ever reach a block's dynamic-Huffman path at this scale.
+		{"multi-MB corpus", testcorpus.Generate(3 << 20), DefaultOptions},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var compressed bytes.Buffer
+			if err := Compress(bytes.NewReader(c.data), &compressed, c.opts); err != nil {
+				t.Fatalf("Compress: %v", err)
+			}
+
+			var decompressed bytes.Buffer
+			if err := Decompress(&compressed, &decompressed); err != nil {
+				t.Fatalf("Decompress: %v", err)
+			}
+
+			if !bytes.Equal(decompressed.Bytes(), c.data) {
+				t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", decompressed.Len(), len(c.data))
+			}
+		})
+	}
+}
+
+func TestCompressRejectsOversizedWindow(t *testing.T) {
+	opts := Options{WindowSize: 100000, BlockSize: 100000}
+	var compressed bytes.Buffer
+	err := Compress(bytes.NewReader(bytes.Repeat([]byte("abcabcabc"), 100)), &compressed, opts)
+	if err == nil {
+		t.Fatal("Compress: expected an error for a WindowSize beyond the distance alphabet's range, got nil")
+	}
+}
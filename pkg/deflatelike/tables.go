@@ -0,0 +1,86 @@
+package deflatelike
+
+// The literal/length alphabet mixes 256 literal byte values with 29 length
+// codes used for back-references, plus an end-of-block marker, giving the
+// 286-symbol alphabet DEFLATE itself uses (RFC 1951 section 3.2.5).
+const (
+	endOfBlockSymbol  = 256
+	firstLengthSymbol = 257
+	litlenAlphabet    = 286
+	distAlphabet      = 30
+)
+
+// maxMatchLength is the longest match a single length code can represent;
+// an LZ77 match longer than this must be split into several back-references
+// at the same distance.
+const maxMatchLength = 258
+
+// lengthBase and lengthExtraBits describe the 29 length codes (257-285):
+// lengthBase[i] is the smallest match length that code 257+i represents, and
+// lengthExtraBits[i] extra bits follow to select among a range of lengths.
+var lengthBase = [29]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 15, 17, 19, 23, 27, 31, 35, 43, 51, 59, 67, 83, 99, 115, 131, 163, 195, 227, 258}
+var lengthExtraBits = [29]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0}
+
+// distBase and distExtraBits describe the 30 distance codes the same way.
+var distBase = [30]int{1, 2, 3, 4, 5, 7, 9, 13, 17, 25, 33, 49, 65, 97, 129, 193, 257, 385, 513, 769, 1025, 1537, 2049, 3073, 4097, 6145, 8193, 12289, 16385, 24577}
+var distExtraBits = [30]int{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+
+// lengthToSymbol maps a match length to its literal/length alphabet symbol
+// plus the extra bits (and their value) needed to recover the exact length.
+func lengthToSymbol(length int) (sym, extraBits, extraVal int) {
+	for i := len(lengthBase) - 1; i >= 0; i-- {
+		if length >= lengthBase[i] {
+			return firstLengthSymbol + i, lengthExtraBits[i], length - lengthBase[i]
+		}
+	}
+	return firstLengthSymbol, 0, 0
+}
+
+// symbolToLength is the inverse of lengthToSymbol.
+func symbolToLength(sym int, extra int) int {
+	return lengthBase[sym-firstLengthSymbol] + extra
+}
+
+// distanceToSymbol maps a match distance to its distance-alphabet symbol
+// plus the extra bits (and their value) needed to recover the exact distance.
+func distanceToSymbol(dist int) (sym, extraBits, extraVal int) {
+	for i := len(distBase) - 1; i >= 0; i-- {
+		if dist >= distBase[i] {
+			return i, distExtraBits[i], dist - distBase[i]
+		}
+	}
+	return 0, 0, 0
+}
+
+// symbolToDistance is the inverse of distanceToSymbol.
+func symbolToDistance(sym int, extra int) int {
+	return distBase[sym] + extra
+}
+
+// fixedLitLenLengths and fixedDistLengths are DEFLATE's predefined code
+// lengths for "fixed Huffman" blocks (RFC 1951 section 3.2.6), used when a
+// block is too small for a custom table to pay for itself.
+func fixedLitLenLengths() map[int]int {
+	lengths := make(map[int]int, litlenAlphabet)
+	for i := 0; i < litlenAlphabet; i++ {
+		switch {
+		case i <= 143:
+			lengths[i] = 8
+		case i <= 255:
+			lengths[i] = 9
+		case i <= 279:
+			lengths[i] = 7
+		default:
+			lengths[i] = 8
+		}
+	}
+	return lengths
+}
+
+func fixedDistLengths() map[int]int {
+	lengths := make(map[int]int, distAlphabet)
+	for i := 0; i < distAlphabet; i++ {
+		lengths[i] = 5
+	}
+	return lengths
+}
@@ -0,0 +1,318 @@
+// Package huffman builds canonical, length-limited Huffman codes over any
+// comparable symbol alphabet and reads/writes them with pkg/bitio. It is the
+// engine shared by the root package's text demo and by pkg/deflatelike's
+// fixed numeric literal/length and distance alphabets.
+package huffman
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+)
+
+// MaxBits bounds the length of any canonical code this package emits, so
+// codes can be decoded with a fixed-size root table. 15 bits matches the
+// limit DEFLATE itself imposes.
+const MaxBits = 15
+
+// Code is a canonical Huffman code: the packed bit pattern plus its length,
+// analogous to the hcode type in compress/flate.
+type Code struct {
+	Bits uint16
+	Len  uint16
+}
+
+// Encoder holds the per-symbol codes for an alphabet of type T, along with a
+// histogram of how many symbols landed at each code length.
+type Encoder[T comparable] struct {
+	Codes    map[T]Code
+	BitCount [MaxBits + 1]int32
+}
+
+// Freq pairs a symbol with how many times it occurred.
+type Freq[T comparable] struct {
+	Symbol T
+	Count  int
+}
+
+// Build computes a canonical, length-limited Huffman code for the given
+// symbol frequencies. Code lengths are chosen with the package-merge
+// algorithm so that no code exceeds MaxBits, then canonical codes are
+// assigned in (length, symbol) order.
+func Build[T comparable](freqs []Freq[T]) *Encoder[T] {
+	lengths := buildCodeLengths(freqs, MaxBits)
+	return FromLengths(lengths)
+}
+
+// pmItem is a node in the package-merge algorithm: either an original symbol
+// (len(indices) == 1) or a "package" formed by merging two lower-level
+// items. indices records every original freqs[] entry this item subsumes.
+type pmItem struct {
+	freq    int64
+	indices []int
+}
+
+// buildCodeLengths returns a code length per symbol such that no length
+// exceeds maxLen, using the package-merge algorithm. list starts as list_1,
+// the leaves themselves; at each subsequent level 2..maxLen, list_{k-1}'s
+// items are paired into "packages" and merged back in with the original
+// leaves to form list_k, truncated to its cheapest 2n-2 items. A symbol's
+// final code length is the number of items in list_maxLen's cheapest 2n-2
+// that it appears in.
+//
+// Two details matter for correctness:
+//   - list_1 must be the bare leaves with no packaging step applied to it --
+//     looping from level 1 instead of 2 packages list_1 with itself an extra
+//     time, inflating every symbol's count by one and overflowing
+//     Encoder.BitCount (sized MaxBits+1) once maxLen leaves no headroom.
+//   - list_k must be truncated to its cheapest 2n-2 items at *every* level,
+//     not just the last one, otherwise the untruncated tail keeps feeding
+//     into later pairings and a symbol's count again grows past maxLen.
+func buildCodeLengths[T comparable](freqs []Freq[T], maxLen int) map[T]int {
+	n := len(freqs)
+	lengths := make(map[T]int, n)
+	if n == 0 {
+		return lengths
+	}
+	if n == 1 {
+		lengths[freqs[0].Symbol] = 1
+		return lengths
+	}
+
+	sorted := make([]Freq[T], n)
+	copy(sorted, freqs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Count < sorted[j].Count })
+
+	base := make([]pmItem, n)
+	for i, f := range sorted {
+		base[i] = pmItem{freq: int64(f.Count), indices: []int{i}}
+	}
+
+	limit := 2*n - 2
+	counts := make([]int, n)
+	list := base // list_1
+	for level := 2; level <= maxLen; level++ {
+		var packages []pmItem
+		for i := 0; i+1 < len(list); i += 2 {
+			packages = append(packages, pmItem{
+				freq:    list[i].freq + list[i+1].freq,
+				indices: mergeIndices(list[i].indices, list[i+1].indices),
+			})
+		}
+		list = mergePMItems(packages, base)
+		if len(list) > limit {
+			list = list[:limit]
+		}
+	}
+
+	take := limit
+	if take > len(list) {
+		take = len(list)
+	}
+	for _, item := range list[:take] {
+		for _, idx := range item.indices {
+			counts[idx]++
+		}
+	}
+
+	for i, f := range sorted {
+		lengths[f.Symbol] = counts[i]
+	}
+	return lengths
+}
+
+// mergeIndices concatenates two leaf-index lists, keeping them sorted so
+// later merges stay well-behaved.
+func mergeIndices(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	sort.Ints(merged)
+	return merged
+}
+
+// mergePMItems merges two slices already sorted by ascending frequency into
+// one sorted slice, the way package-merge combines this level's packages
+// with the original leaf list to build the next level.
+func mergePMItems(a, b []pmItem) []pmItem {
+	merged := make([]pmItem, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i].freq <= b[j].freq {
+			merged = append(merged, a[i])
+			i++
+		} else {
+			merged = append(merged, b[j])
+			j++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	merged = append(merged, b[j:]...)
+	return merged
+}
+
+// FromLengths assigns canonical Huffman codes from explicit per-symbol
+// lengths, without running package-merge. This is how DEFLATE's fixed
+// Huffman tables are built, and how a dynamic block's decoder rebuilds the
+// encoder's tree from the lengths stored in the block header.
+func FromLengths[T comparable](lengths map[T]int) *Encoder[T] {
+	type symLen struct {
+		sym T
+		len int
+	}
+	syms := make([]symLen, 0, len(lengths))
+	enc := &Encoder[T]{Codes: make(map[T]Code, len(lengths))}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		syms = append(syms, symLen{s, l})
+		enc.BitCount[l]++
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		if syms[i].len != syms[j].len {
+			return syms[i].len < syms[j].len
+		}
+		return fmt.Sprint(syms[i].sym) < fmt.Sprint(syms[j].sym)
+	})
+
+	var code uint16
+	prevLen := 0
+	for _, sl := range syms {
+		code <<= uint(sl.len - prevLen)
+		enc.Codes[sl.sym] = Code{Bits: code, Len: uint16(sl.len)}
+		code++
+		prevLen = sl.len
+	}
+	return enc
+}
+
+// Write emits a single canonical code, most-significant bit first.
+func Write(bw *bitio.Writer, c Code) error {
+	return bw.WriteBits(uint32(c.Bits), int(c.Len))
+}
+
+// rootBits is the width of the first-level decode table. Codes up to this
+// length resolve in a single lookup; longer codes overflow into a
+// per-prefix subtable, mirroring the two-level table flate's decoder uses.
+const rootBits = 9
+const subBits = MaxBits - rootBits
+
+type decodeEntry[T comparable] struct {
+	sym  T
+	len  uint8
+	long bool
+}
+
+// Decoder is a table-driven decoder built once from an Encoder.
+type Decoder[T comparable] struct {
+	root [1 << rootBits]decodeEntry[T]
+	subs map[uint16]*[1 << subBits]decodeEntry[T]
+}
+
+// NewDecoder builds a Decoder from the codes in enc.
+func NewDecoder[T comparable](enc *Encoder[T]) *Decoder[T] {
+	d := &Decoder[T]{subs: make(map[uint16]*[1 << subBits]decodeEntry[T])}
+	for sym, c := range enc.Codes {
+		if int(c.Len) <= rootBits {
+			fillEntries(d.root[:], c.Bits, int(c.Len), rootBits, sym)
+			continue
+		}
+		remaining := int(c.Len) - rootBits
+		prefix := c.Bits >> uint(remaining)
+		d.root[prefix] = decodeEntry[T]{long: true}
+		sub := d.subs[prefix]
+		if sub == nil {
+			sub = &[1 << subBits]decodeEntry[T]{}
+			d.subs[prefix] = sub
+		}
+		lowBits := c.Bits & (1<<uint(remaining) - 1)
+		fillEntries(sub[:], lowBits, remaining, subBits, sym)
+	}
+	return d
+}
+
+// fillEntries left-justifies a code of the given length within a
+// tableBits-wide table and fills every entry sharing that prefix, since a
+// shorter code consumes fewer of the bits the table was indexed with.
+func fillEntries[T comparable](table []decodeEntry[T], code uint16, length, tableBits int, sym T) {
+	shift := tableBits - length
+	base := int(code) << uint(shift)
+	entry := decodeEntry[T]{sym: sym, len: uint8(length)}
+	for i := 0; i < 1<<uint(shift); i++ {
+		table[base+i] = entry
+	}
+}
+
+// Decode reads exactly one symbol from br and returns it along with the
+// number of bits it consumed.
+func (d *Decoder[T]) Decode(br *bitio.Reader) (T, int, error) {
+	var zero T
+	avail, err := br.PeekBits(rootBits)
+	if err != nil {
+		return d.decodeShort(br)
+	}
+	entry := d.root[avail]
+	if !entry.long {
+		if entry.len == 0 {
+			return zero, 0, fmt.Errorf("huffman: invalid code at current position")
+		}
+		br.ConsumeBits(int(entry.len))
+		return entry.sym, int(entry.len), nil
+	}
+	sub := d.subs[uint16(avail)]
+	if sub == nil {
+		return zero, 0, fmt.Errorf("huffman: invalid long code at current position")
+	}
+	br.ConsumeBits(rootBits)
+	extra, err := br.PeekBits(subBits)
+	if err != nil {
+		// Fewer than subBits bits remain in the stream, which is expected
+		// when a long code is the very last symbol -- grow the peek width
+		// one bit at a time, the same trick decodeShort uses at the root
+		// level.
+		sym, n, err := decodeFromTable(br, sub[:], subBits)
+		if err != nil {
+			return zero, 0, err
+		}
+		return sym, rootBits + n, nil
+	}
+	subEntry := sub[extra]
+	if subEntry.len == 0 {
+		return zero, 0, fmt.Errorf("huffman: invalid code in overflow table")
+	}
+	br.ConsumeBits(int(subEntry.len))
+	return subEntry.sym, rootBits + int(subEntry.len), nil
+}
+
+// decodeShort handles the final symbol of a stream when fewer than rootBits
+// bits remain, by growing the peek width one bit at a time until a matching
+// root-table entry (necessarily a short code) is found.
+func (d *Decoder[T]) decodeShort(br *bitio.Reader) (T, int, error) {
+	return decodeFromTable(br, d.root[:], rootBits)
+}
+
+// decodeFromTable handles the final symbol of a stream when fewer than
+// tableBits bits remain before the underlying reader runs dry, by growing
+// the peek width one bit at a time until a matching table entry -- of a
+// length equal to however many bits were actually peeked -- is found. It is
+// used both for the root table (tableBits == rootBits) and, when a long
+// code happens to be the very last symbol, for its subtable (tableBits ==
+// subBits).
+func decodeFromTable[T comparable](br *bitio.Reader, table []decodeEntry[T], tableBits int) (T, int, error) {
+	var zero T
+	for n := 1; n <= tableBits; n++ {
+		bits, err := br.PeekBits(n)
+		if err != nil {
+			return zero, 0, err
+		}
+		shift := tableBits - n
+		entry := table[int(bits)<<uint(shift)]
+		if entry.len == uint8(n) {
+			br.ConsumeBits(n)
+			return entry.sym, n, nil
+		}
+	}
+	return zero, 0, fmt.Errorf("huffman: truncated bitstream")
+}
@@ -0,0 +1,213 @@
+package huffman
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SkippyHub/huffmanlz77compression/pkg/bitio"
+)
+
+func TestBuildAndDecodeRoundTripBytes(t *testing.T) {
+	cases := [][]byte{
+		[]byte("a"),
+		[]byte("abracadabra"),
+		bytes.Repeat([]byte{0xFF}, 10),
+		[]byte{0x00, 0x01, 0x02, 0x00, 0xFF, 0x80, 0x00},
+	}
+
+	for _, data := range cases {
+		var counts [256]int
+		for _, b := range data {
+			counts[b]++
+		}
+		var freqs []Freq[byte]
+		for sym, count := range counts {
+			if count > 0 {
+				freqs = append(freqs, Freq[byte]{Symbol: byte(sym), Count: count})
+			}
+		}
+		enc := Build(freqs)
+
+		var buf bytes.Buffer
+		bw := bitio.NewWriter(&buf)
+		nbits := 0
+		for _, b := range data {
+			code := enc.Codes[b]
+			if err := Write(bw, code); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			nbits += int(code.Len)
+		}
+		if err := bw.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+
+		dec := NewDecoder(enc)
+		br := bitio.NewReader(bytes.NewReader(buf.Bytes()))
+		var got []byte
+		read := 0
+		for read < nbits {
+			sym, n, err := dec.Decode(br)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			got = append(got, sym)
+			read += n
+		}
+
+		if !bytes.Equal(got, data) {
+			t.Fatalf("round trip mismatch for %q: got %v", data, got)
+		}
+	}
+}
+
+func TestFromLengthsMatchesBuild(t *testing.T) {
+	freqs := []Freq[rune]{
+		{Symbol: 'a', Count: 10},
+		{Symbol: 'b', Count: 5},
+		{Symbol: 'c', Count: 1},
+		{Symbol: 'd', Count: 1},
+	}
+	built := Build(freqs)
+
+	lengths := make(map[rune]int, len(built.Codes))
+	for sym, code := range built.Codes {
+		lengths[sym] = int(code.Len)
+	}
+	rebuilt := FromLengths(lengths)
+
+	for sym, code := range built.Codes {
+		other, ok := rebuilt.Codes[sym]
+		if !ok {
+			t.Fatalf("FromLengths dropped symbol %q", sym)
+		}
+		if other != code {
+			t.Fatalf("FromLengths produced a different code for %q: got %+v, want %+v", sym, other, code)
+		}
+	}
+}
+
+func TestBuildSingleSymbolAlphabet(t *testing.T) {
+	enc := Build([]Freq[byte]{{Symbol: 'x', Count: 42}})
+	code, ok := enc.Codes['x']
+	if !ok || code.Len == 0 {
+		t.Fatalf("expected a non-empty code for the sole symbol, got %+v (ok=%v)", code, ok)
+	}
+}
+
+// TestDecodeLongCodesRoundTrip exercises NewDecoder/Decode over a large,
+// skewed alphabet whose rarest symbols land at codes longer than rootBits
+// and so must be routed through the overflow subtable. A bug once left the
+// root table's entries for such codes entirely unset, so every long code
+// failed to decode with "invalid code at current position" regardless of
+// its position in the stream; a second bug undercounted the subtable
+// lookup's consumed bits and broke peeking the final bits of a stream that
+// ends on a long code. This alphabet size and skew (mirroring
+// TestBuildSkewedLargeAlphabet) reliably produces codes on both sides of
+// rootBits, and encoding every symbol back to back -- ending on whichever
+// symbol happens to be last -- exercises both the short and long decode
+// paths, including right at end of stream.
+func TestDecodeLongCodesRoundTrip(t *testing.T) {
+	const n = 256
+	freqs := make([]Freq[int], n)
+	for i := 0; i < n; i++ {
+		freqs[i] = Freq[int]{Symbol: i, Count: 1 + (i*i)%1000}
+	}
+	enc := Build(freqs)
+
+	hasLongCode := false
+	for _, c := range enc.Codes {
+		if int(c.Len) > rootBits {
+			hasLongCode = true
+			break
+		}
+	}
+	if !hasLongCode {
+		t.Fatal("test setup bug: expected at least one code longer than rootBits")
+	}
+
+	var buf bytes.Buffer
+	bw := bitio.NewWriter(&buf)
+	var want []int
+	for i := 0; i < n; i++ {
+		sym := i % n
+		want = append(want, sym)
+		if err := Write(bw, enc.Codes[sym]); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	dec := NewDecoder(enc)
+	br := bitio.NewReader(bytes.NewReader(buf.Bytes()))
+	var got []int
+	for range want {
+		sym, _, err := dec.Decode(br)
+		if err != nil {
+			t.Fatalf("Decode: %v (decoded %d of %d symbols)", err, len(got), len(want))
+		}
+		got = append(got, sym)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("symbol %d: got %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+// TestBuildSkewedLargeAlphabet exercises buildCodeLengths with realistic
+// litlen-alphabet-sized (150-286 symbol), Zipf-skewed frequency tables. A
+// package-merge bug once let a symbol's count grow past MaxBits, panicking
+// FromLengths' BitCount histogram; this guards against that regressing by
+// also checking every code obeys MaxBits and the Kraft inequality holds
+// exactly (sum of 2^-len over all symbols equals 1 for a complete code).
+func TestBuildSkewedLargeAlphabet(t *testing.T) {
+	for _, n := range []int{150, 200, 256, 286} {
+		freqs := make([]Freq[int], n)
+		for i := 0; i < n; i++ {
+			// A sharply skewed (Zipf-like) distribution: frequencies span
+			// several orders of magnitude, the kind of diversity a real
+			// HTML page or source file's byte histogram has.
+			freqs[i] = Freq[int]{Symbol: i, Count: 1 + (i*i)%1000}
+		}
+		enc := Build(freqs)
+
+		var kraftNum, kraftDen int64 = 0, 1
+		maxLen := 0
+		for _, sym := range freqs {
+			code, ok := enc.Codes[sym.Symbol]
+			if !ok {
+				t.Fatalf("n=%d: no code for symbol %d", n, sym.Symbol)
+			}
+			if int(code.Len) > MaxBits {
+				t.Fatalf("n=%d: symbol %d has length %d, exceeds MaxBits %d", n, sym.Symbol, code.Len, MaxBits)
+			}
+			if int(code.Len) > maxLen {
+				maxLen = int(code.Len)
+			}
+			// Accumulate sum(2^-len) as an exact fraction to avoid float
+			// rounding masking a real Kraft violation.
+			kraftNum, kraftDen = addFraction(kraftNum, kraftDen, 1, int64(1)<<uint(code.Len))
+		}
+		if kraftNum != kraftDen {
+			t.Fatalf("n=%d: Kraft inequality not tight: %d/%d", n, kraftNum, kraftDen)
+		}
+		t.Logf("n=%d: maxLen=%d", n, maxLen)
+	}
+}
+
+// addFraction adds a/b to num/den and returns a new fraction reduced to a
+// common denominator, used to check the Kraft inequality exactly.
+func addFraction(num, den, addNum, addDen int64) (int64, int64) {
+	commonDen := den
+	if addDen > commonDen {
+		commonDen = addDen
+	}
+	// den and addDen are always powers of two here, so the larger is a
+	// common multiple of both.
+	num = num * (commonDen / den)
+	addNum = addNum * (commonDen / addDen)
+	return num + addNum, commonDen
+}
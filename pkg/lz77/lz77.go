@@ -0,0 +1,87 @@
+// Package lz77 implements the LZ77 sliding-window matcher used by
+// pkg/deflatelike to turn raw bytes into a stream of literal and
+// back-reference tokens.
+package lz77
+
+// Token is a single LZ77 output unit: a back-reference of Length bytes found
+// Distance bytes back in the window, followed by the literal byte Next that
+// broke the match (or that stands alone when Length is 0).
+type Token struct {
+	Distance int
+	Length   int
+	Next     byte
+}
+
+// minMatchBytes is the shortest run the hash chain indexes; matches shorter
+// than this are not worth a distance+length pair's overhead.
+const minMatchBytes = 3
+
+// Options configures how hard Compress searches for matches.
+type Options struct {
+	// WindowSize bounds how far back a match may point. Zero selects 32768,
+	// the classic DEFLATE window.
+	WindowSize int
+	// Level trades match-finding effort for compression ratio, 1 (fastest)
+	// through 9 (best), the same knob flate/klauspost expose. Zero selects 6.
+	Level int
+}
+
+// maxChainByLevel bounds how many hash-chain links longestMatchAt walks per
+// position at each level, mirroring the table klauspost's flate fork uses.
+var maxChainByLevel = [10]int{0, 4, 8, 16, 32, 64, 128, 256, 1024, 4096}
+
+// Compress finds LZ77 tokens in input using a window of windowSize bytes at
+// the default level. It is a thin wrapper over CompressLevel, kept so
+// existing callers do not need to adopt Options.
+func Compress(input []byte, windowSize int) []Token {
+	return CompressLevel(input, Options{WindowSize: windowSize, Level: 6})
+}
+
+// CompressLevel finds LZ77 tokens in input using a hash-chain matcher bounded
+// by opts.Level, with one step of lazy matching: after finding a match at
+// position i, it also checks i+1; if that match is strictly longer, a
+// literal is emitted for input[i] and the longer match is used instead.
+func CompressLevel(input []byte, opts Options) []Token {
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = 32768
+	}
+	level := opts.Level
+	if level <= 0 || level >= len(maxChainByLevel) {
+		level = 6
+	}
+	if len(input) == 0 {
+		return nil
+	}
+
+	m := newMatcher(input, opts.WindowSize, maxChainByLevel[level])
+
+	var tokens []Token
+	i := 0
+	for i < len(input) {
+		m.ensureInserted(i)
+		length, distance := m.longestMatchAt(i)
+
+		if length >= minMatchBytes && i+1 < len(input) {
+			m.ensureInserted(i + 1)
+			nextLength, nextDistance := m.longestMatchAt(i + 1)
+			if nextLength > length {
+				tokens = append(tokens, Token{Next: input[i]})
+				i++
+				_ = nextDistance
+				continue
+			}
+		}
+
+		nextChar := byte(0)
+		if i+length < len(input) {
+			nextChar = input[i+length]
+		}
+		tokens = append(tokens, Token{Distance: distance, Length: length, Next: nextChar})
+
+		// Index every position the match covers so later matches can still
+		// reference into it, then skip past the match and its Next byte.
+		m.ensureInserted(min(i+length, len(input)-1))
+		i += length + 1
+	}
+	return tokens
+}
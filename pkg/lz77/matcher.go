@@ -0,0 +1,98 @@
+package lz77
+
+// hashBits sizes the head table; 15 bits (32768 buckets) is the classic
+// zlib/DEFLATE choice for a 32K window.
+const hashBits = 15
+const hashSize = 1 << hashBits
+const hashMask = hashSize - 1
+
+// hashShift spreads minMatchBytes bytes across hashBits bits of the rolling
+// hash, the same formula zlib uses for its own hash table.
+const hashShift = (hashBits + minMatchBytes - 1) / minMatchBytes
+
+// matcher indexes the 3-byte prefixes of data into hash chains (head/prev,
+// zlib-style) so longestMatchAt can find candidate matches without rescanning
+// the whole window for every position.
+type matcher struct {
+	data       []byte
+	windowSize int
+	maxChain   int
+
+	head []int32 // head[hash] = most recently inserted position with that hash, or -1
+	prev []int32 // prev[pos] = previous position with the same hash as pos
+
+	hash     uint32
+	inserted int // positions < inserted have already been added to the chains
+}
+
+func newMatcher(data []byte, windowSize, maxChain int) *matcher {
+	m := &matcher{
+		data:       data,
+		windowSize: windowSize,
+		maxChain:   maxChain,
+		head:       make([]int32, hashSize),
+		prev:       make([]int32, len(data)),
+	}
+	for i := range m.head {
+		m.head[i] = -1
+	}
+	if len(data) >= 2 {
+		m.hash = (uint32(data[0])<<hashShift ^ uint32(data[1])) & hashMask
+	}
+	return m
+}
+
+// ensureInserted indexes every position up to and including pos, in order,
+// so the rolling hash stays consistent.
+func (m *matcher) ensureInserted(pos int) {
+	for m.inserted <= pos {
+		m.insertOne(m.inserted)
+		m.inserted++
+	}
+}
+
+// insertOne rolls pos+2's byte into the hash and chains pos onto it. The last
+// two positions in data have no 3-byte prefix and are left unindexed.
+func (m *matcher) insertOne(pos int) {
+	if pos+2 >= len(m.data) {
+		return
+	}
+	m.hash = ((m.hash << hashShift) ^ uint32(m.data[pos+2])) & hashMask
+	m.prev[pos] = m.head[m.hash]
+	m.head[m.hash] = int32(pos)
+}
+
+// longestMatchAt walks the hash chain for the 3-byte prefix at pos, following
+// at most maxChain links within windowSize, and returns the longest match
+// found along with its distance. It assumes ensureInserted(pos) has already
+// been called.
+func (m *matcher) longestMatchAt(pos int) (length, distance int) {
+	if pos+2 >= len(m.data) {
+		return 0, 0
+	}
+
+	limit := pos - m.windowSize
+	if limit < 0 {
+		limit = 0
+	}
+	maxLen := len(m.data) - pos
+
+	candidate := m.prev[pos]
+	for chain := m.maxChain; candidate >= int32(limit) && chain > 0; chain-- {
+		l := matchLength(m.data, int(candidate), pos, maxLen)
+		if l > length {
+			length = l
+			distance = pos - int(candidate)
+		}
+		candidate = m.prev[candidate]
+	}
+	return length, distance
+}
+
+func matchLength(data []byte, i, j, maxLen int) int {
+	l := 0
+	for l < maxLen && data[i+l] == data[j+l] {
+		l++
+	}
+	return l
+}
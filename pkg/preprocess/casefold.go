@@ -0,0 +1,69 @@
+package preprocess
+
+// CaseFold losslessly folds runs of ASCII uppercase letters to lowercase,
+// recording each run as an escape byte plus a run-length instead of the old
+// applyShiftString's '↑'/'↓' runes, which could collide with real input.
+// Any literal occurrence of the escape byte itself is byte-stuffed -- the
+// same technique PPP uses to escape its control octets -- so CaseFold is
+// safe on arbitrary binary input, not just text.
+type CaseFold struct{}
+
+// caseFoldEscape introduces either a byte-stuffed literal escape byte
+// (run-length 0) or an upcoming uppercase run (run-length > 0).
+const caseFoldEscape = 0x00
+
+func isUpperASCII(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+func (CaseFold) Encode(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	i := 0
+	for i < len(data) {
+		b := data[i]
+		switch {
+		case b == caseFoldEscape:
+			out = append(out, caseFoldEscape)
+			out = appendVarint(out, 0)
+			i++
+		case isUpperASCII(b):
+			start := i
+			for i < len(data) && isUpperASCII(data[i]) {
+				i++
+			}
+			out = append(out, caseFoldEscape)
+			out = appendVarint(out, uint64(i-start))
+			for _, c := range data[start:i] {
+				out = append(out, c-'A'+'a')
+			}
+		default:
+			out = append(out, b)
+			i++
+		}
+	}
+	return out
+}
+
+func (CaseFold) Decode(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	i := 0
+	for i < len(data) {
+		if data[i] != caseFoldEscape {
+			out = append(out, data[i])
+			i++
+			continue
+		}
+		i++
+		var n uint64
+		n, i = readVarint(data, i)
+		if n == 0 {
+			out = append(out, caseFoldEscape)
+			continue
+		}
+		for k := uint64(0); k < n; k++ {
+			out = append(out, data[i]-'a'+'A')
+			i++
+		}
+	}
+	return out
+}
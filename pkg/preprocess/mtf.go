@@ -0,0 +1,42 @@
+package preprocess
+
+// MTF is the move-to-front transform: it remaps each byte to the position
+// where it currently sits in a 0..255 list, then moves that byte to the
+// front. Run after BWT, recently-seen bytes (which BWT clusters together)
+// collapse to small indices, producing a skewed, Huffman-friendly
+// distribution.
+type MTF struct{}
+
+func (MTF) Encode(data []byte) []byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i)
+	}
+	out := make([]byte, len(data))
+	for i, b := range data {
+		idx := 0
+		for table[idx] != b {
+			idx++
+		}
+		out[i] = byte(idx)
+		copy(table[1:idx+1], table[:idx])
+		table[0] = b
+	}
+	return out
+}
+
+func (MTF) Decode(data []byte) []byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i)
+	}
+	out := make([]byte, len(data))
+	for i, raw := range data {
+		idx := int(raw)
+		b := table[idx]
+		out[i] = b
+		copy(table[1:idx+1], table[:idx])
+		table[0] = b
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+// Package preprocess provides pluggable, reversible byte-stream transforms
+// that run ahead of entropy coding -- the role applyShiftString used to fill
+// on its own, generalized so stages can be composed (e.g. BWT -> MTF) and so
+// none of them need to invent their own collision-prone control characters.
+package preprocess
+
+// Preprocessor is a reversible byte-stream transform: Decode(Encode(b)) must
+// reproduce b exactly.
+type Preprocessor interface {
+	Encode(data []byte) []byte
+	Decode(data []byte) []byte
+}
+
+// Chain composes preprocessors front-to-back: Encode runs them in order,
+// Decode undoes them in reverse order. A Chain is itself a Preprocessor, so
+// chains can nest.
+type Chain []Preprocessor
+
+func (c Chain) Encode(data []byte) []byte {
+	for _, p := range c {
+		data = p.Encode(data)
+	}
+	return data
+}
+
+func (c Chain) Decode(data []byte) []byte {
+	for i := len(c) - 1; i >= 0; i-- {
+		data = c[i].Decode(data)
+	}
+	return data
+}
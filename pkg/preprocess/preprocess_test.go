@@ -0,0 +1,79 @@
+package preprocess
+
+import (
+	"bytes"
+	"testing"
+)
+
+func roundTripCases() [][]byte {
+	return [][]byte{
+		nil,
+		{},
+		{'a'},
+		{0x00},
+		{0xFF},
+		bytes.Repeat([]byte{'x'}, 50),
+		bytes.Repeat([]byte{0x00}, 10),
+		bytes.Repeat([]byte{0xFF}, 10),
+		{0x00, 0xFF, 0x00, 0xFF},
+		[]byte("The Quick BROWN Fox Jumps Over The Lazy DOG"),
+		[]byte("abracadabra"),
+	}
+}
+
+func TestCaseFoldRoundTrip(t *testing.T) {
+	for _, data := range roundTripCases() {
+		got := CaseFold{}.Decode(CaseFold{}.Encode(data))
+		if !bytes.Equal(got, data) && !(len(got) == 0 && len(data) == 0) {
+			t.Fatalf("CaseFold round trip mismatch for %v: got %v", data, got)
+		}
+	}
+}
+
+// TestCaseFoldStuffsLiteralEscapeByte exercises the byte-stuffing path: a
+// literal 0x00 in the input is caseFoldEscape itself, so it must round-trip
+// through a run-length-0 escape rather than being misread as the start of an
+// uppercase run.
+func TestCaseFoldStuffsLiteralEscapeByte(t *testing.T) {
+	data := []byte{'a', 0x00, 'b', 0x00, 0x00, 'C'}
+	encoded := CaseFold{}.Encode(data)
+	for i, b := range encoded {
+		if b == caseFoldEscape {
+			if i+1 >= len(encoded) {
+				t.Fatalf("escape byte at end of encoded output with no run-length: %v", encoded)
+			}
+		}
+	}
+	got := CaseFold{}.Decode(encoded)
+	if !bytes.Equal(got, data) {
+		t.Fatalf("CaseFold round trip mismatch for literal escape bytes: got %v, want %v", got, data)
+	}
+}
+
+func TestMTFRoundTrip(t *testing.T) {
+	for _, data := range roundTripCases() {
+		got := MTF{}.Decode(MTF{}.Encode(data))
+		if !bytes.Equal(got, data) && !(len(got) == 0 && len(data) == 0) {
+			t.Fatalf("MTF round trip mismatch for %v: got %v", data, got)
+		}
+	}
+}
+
+func TestBWTRoundTrip(t *testing.T) {
+	for _, data := range roundTripCases() {
+		got := BWT{}.Decode(BWT{}.Encode(data))
+		if !bytes.Equal(got, data) && !(len(got) == 0 && len(data) == 0) {
+			t.Fatalf("BWT round trip mismatch for %v: got %v", data, got)
+		}
+	}
+}
+
+func TestChainBWTMTFCaseFoldRoundTrip(t *testing.T) {
+	chain := Chain{BWT{}, MTF{}, CaseFold{}}
+	for _, data := range roundTripCases() {
+		got := chain.Decode(chain.Encode(data))
+		if !bytes.Equal(got, data) && !(len(got) == 0 && len(data) == 0) {
+			t.Fatalf("Chain round trip mismatch for %v: got %v", data, got)
+		}
+	}
+}
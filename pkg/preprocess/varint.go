@@ -0,0 +1,27 @@
+package preprocess
+
+// appendVarint appends v to out as a little-endian base-128 varint.
+func appendVarint(out []byte, v uint64) []byte {
+	for v >= 0x80 {
+		out = append(out, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(out, byte(v))
+}
+
+// readVarint reads a varint written by appendVarint starting at pos, and
+// returns the value along with the position just past it.
+func readVarint(data []byte, pos int) (uint64, int) {
+	var v uint64
+	var shift uint
+	for {
+		b := data[pos]
+		pos++
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	return v, pos
+}
@@ -0,0 +1,97 @@
+package tokendict
+
+// StaticTable holds well-known HTML/CSS tokens and common English words
+// available to every encoder and decoder without ever being transmitted,
+// mirroring HPACK's static table of common HTTP header names and values
+// (RFC 7541 appendix A).
+var StaticTable = []string{
+	"html", "head", "body", "div", "span", "p", "a", "img", "ul", "li",
+	"table", "tr", "td", "th", "script", "style", "link", "meta", "title",
+	"h1", "h2", "h3", "class", "id", "href", "src", "width", "height",
+	"type", "rel", "charset", "alt", "name", "content", "value",
+	"the", "and", "for", "this", "that", "with", "you", "your", "are",
+}
+
+// entrySize mirrors HPACK's accounting rule for table entries (RFC 7541
+// section 4.1): each entry costs its token length plus 32 bytes of
+// bookkeeping overhead, so a table's "size" is never just the sum of its
+// token lengths.
+func entrySize(token string) int {
+	return len(token) + 32
+}
+
+// dynamicTable is a bounded, size-evicted table of recently seen tokens,
+// indexed the way HPACK indexes its dynamic table: the most recently
+// inserted entry is always index 0, so an encoder and a decoder that see the
+// same tokens in the same order always agree on every index without ever
+// transmitting the table itself.
+type dynamicTable struct {
+	entries  []string // entries[0] is the most recently inserted
+	size     int
+	capacity int
+}
+
+func newDynamicTable(capacity int) *dynamicTable {
+	return &dynamicTable{capacity: capacity}
+}
+
+// insert adds token as the newest entry, evicting the oldest entries until
+// the table fits back within capacity. A token too large to ever fit simply
+// empties the table, matching HPACK's handling of oversized entries.
+func (t *dynamicTable) insert(token string) {
+	cost := entrySize(token)
+	if cost > t.capacity {
+		t.entries = nil
+		t.size = 0
+		return
+	}
+	t.entries = append([]string{token}, t.entries...)
+	t.size += cost
+	for t.size > t.capacity {
+		last := len(t.entries) - 1
+		t.size -= entrySize(t.entries[last])
+		t.entries = t.entries[:last]
+	}
+}
+
+// at returns the dynamic-table entry at index i (0 = most recently
+// inserted).
+func (t *dynamicTable) at(i int) (string, bool) {
+	if i < 0 || i >= len(t.entries) {
+		return "", false
+	}
+	return t.entries[i], true
+}
+
+// find returns the lowest dynamic-table index holding token, if any.
+func (t *dynamicTable) find(token string) (int, bool) {
+	for i, e := range t.entries {
+		if e == token {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lookup resolves a combined static+dynamic index: indices below
+// len(StaticTable) address the static table, the rest address dyn.
+func lookup(dyn *dynamicTable, index int) (string, bool) {
+	if index >= 0 && index < len(StaticTable) {
+		return StaticTable[index], true
+	}
+	return dyn.at(index - len(StaticTable))
+}
+
+// findIndex is the inverse of lookup: it returns the combined index for
+// token if either table already holds it, preferring the static table.
+func findIndex(dyn *dynamicTable, token string) (int, bool) {
+	for i, s := range StaticTable {
+		if s == token {
+			return i, true
+		}
+	}
+	if i, ok := dyn.find(token); ok {
+		return len(StaticTable) + i, true
+	}
+	return 0, false
+}
@@ -0,0 +1,161 @@
+// Package tokendict implements an HPACK-inspired (RFC 7541) static+dynamic
+// table front-end for text with many repeated tokens -- HTML tag names,
+// attribute names, common words. It is meant to sit in front of this
+// repository's preprocess + Huffman pipeline: repeated tokens are replaced
+// by a short table reference instead of their literal bytes, and the
+// decoder rebuilds the same table as it reads, so the table itself is never
+// transmitted.
+package tokendict
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultCapacity mirrors HPACK's default SETTINGS_HEADER_TABLE_SIZE.
+const defaultCapacity = 4096
+
+// Control runes delimit tokendict's output stream. They're drawn from the
+// Unicode Private Use Area, the same trick preprocess.CaseFold uses for its
+// escape byte, so they can't collide with real input text.
+const (
+	markerIndexed         = '' // followed by a varint combined-table index
+	markerLiteral         = '' // followed by a varint length and the literal bytes; inserted into the dynamic table
+	markerLiteralNoInsert = '' // same, but too large (or empty) to bother inserting
+)
+
+// Pair is one (token, separator) unit: Separator is the run of non-word
+// runes immediately following Token, possibly empty. Joining every pair's
+// Token then Separator in order reproduces the original string exactly.
+type Pair struct {
+	Token     string
+	Separator string
+}
+
+// isWordRune reports whether r can be part of a token: letters, digits, and
+// hyphen, so hyphenated HTML/CSS names like "font-size" stay one token.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-'
+}
+
+// Tokenize splits s into a sequence of (token, separator) pairs.
+func Tokenize(s string) []Pair {
+	var pairs []Pair
+	runes := []rune(s)
+	i := 0
+	for i < len(runes) {
+		start := i
+		for i < len(runes) && isWordRune(runes[i]) {
+			i++
+		}
+		token := string(runes[start:i])
+
+		start = i
+		for i < len(runes) && !isWordRune(runes[i]) {
+			i++
+		}
+		separator := string(runes[start:i])
+
+		pairs = append(pairs, Pair{Token: token, Separator: separator})
+	}
+	return pairs
+}
+
+// Encode tokenizes s and replaces each token found in the static+dynamic
+// table with a table reference, inserting new literals into the dynamic
+// table for future reuse. The result is a lossless rune-safe transform
+// meant to run directly ahead of preprocess.CaseFold and Huffman coding.
+func Encode(s string) string {
+	dyn := newDynamicTable(defaultCapacity)
+	var out strings.Builder
+	for _, pair := range Tokenize(s) {
+		writeToken(&out, dyn, pair.Token)
+		writeVarint(&out, uint64(len([]rune(pair.Separator))))
+		out.WriteString(pair.Separator)
+	}
+	return out.String()
+}
+
+// Decode reverses Encode, rebuilding the same dynamic table state as it
+// consumes table references so it never needs the table transmitted.
+func Decode(s string) string {
+	dyn := newDynamicTable(defaultCapacity)
+	runes := []rune(s)
+	var out strings.Builder
+	pos := 0
+	for pos < len(runes) {
+		token, newPos := readToken(runes, pos, dyn)
+		pos = newPos
+		out.WriteString(token)
+
+		sepLen, newPos := readVarint(runes, pos)
+		pos = newPos
+		out.WriteString(string(runes[pos : pos+int(sepLen)]))
+		pos += int(sepLen)
+	}
+	return out.String()
+}
+
+func writeToken(out *strings.Builder, dyn *dynamicTable, token string) {
+	if token != "" {
+		if index, ok := findIndex(dyn, token); ok {
+			out.WriteRune(markerIndexed)
+			writeVarint(out, uint64(index))
+			return
+		}
+	}
+	if token != "" && entrySize(token) <= defaultCapacity {
+		out.WriteRune(markerLiteral)
+		dyn.insert(token)
+	} else {
+		out.WriteRune(markerLiteralNoInsert)
+	}
+	writeVarint(out, uint64(len([]rune(token))))
+	out.WriteString(token)
+}
+
+func readToken(runes []rune, pos int, dyn *dynamicTable) (token string, newPos int) {
+	marker := runes[pos]
+	pos++
+	switch marker {
+	case markerIndexed:
+		var index uint64
+		index, pos = readVarint(runes, pos)
+		token, _ = lookup(dyn, int(index))
+		return token, pos
+	default: // markerLiteral, markerLiteralNoInsert
+		var length uint64
+		length, pos = readVarint(runes, pos)
+		token = string(runes[pos : pos+int(length)])
+		pos += int(length)
+		if marker == markerLiteral {
+			dyn.insert(token)
+		}
+		return token, pos
+	}
+}
+
+// writeVarint writes v as a little-endian base-128 varint, one byte per
+// rune (valid low-value runes, so the output stays safe to range over).
+func writeVarint(out *strings.Builder, v uint64) {
+	for v >= 0x80 {
+		out.WriteRune(rune(byte(v) | 0x80))
+		v >>= 7
+	}
+	out.WriteRune(rune(byte(v)))
+}
+
+func readVarint(runes []rune, pos int) (uint64, int) {
+	var v uint64
+	var shift uint
+	for {
+		b := byte(runes[pos])
+		pos++
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	return v, pos
+}
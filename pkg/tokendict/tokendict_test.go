@@ -0,0 +1,97 @@
+package tokendict
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cases := []string{
+		"",
+		"   ",
+		"<div class=\"content\"><p>the quick brown fox</p></div>",
+		strings.Repeat("<span class=\"highlight\">", 50),
+	}
+	for _, s := range cases {
+		got := Decode(Encode(s))
+		if got != s {
+			t.Fatalf("round trip mismatch for %q: got %q", s, got)
+		}
+	}
+}
+
+// TestEncodeDecodeStaticTableCollision exercises tokens that already live in
+// StaticTable: they must resolve to a static index rather than ever being
+// inserted into (and so evicted from) the dynamic table.
+func TestEncodeDecodeStaticTableCollision(t *testing.T) {
+	s := "div div div span span html head body div"
+	got := Decode(Encode(s))
+	if got != s {
+		t.Fatalf("round trip mismatch for %q: got %q", s, got)
+	}
+}
+
+// TestDynamicTableEviction drives the dynamic table past its capacity with a
+// long run of distinct tokens, forcing repeated evictions, then repeats
+// earlier tokens to check the encoder and decoder still agree on every index
+// once old entries have aged out.
+func TestDynamicTableEviction(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 500; i++ {
+		b.WriteString("tok")
+		b.WriteString(strings.Repeat("x", i%7+1))
+		b.WriteByte(' ')
+	}
+	// Tokens from early in the stream should have been evicted from the
+	// dynamic table by now; referencing them again must fall back to a
+	// fresh literal insertion rather than resolving to a stale index.
+	b.WriteString("tokx tokxx")
+	s := b.String()
+
+	got := Decode(Encode(s))
+	if got != s {
+		t.Fatalf("round trip mismatch after eviction: got %q, want %q", got, s)
+	}
+}
+
+func TestDynamicTableInsertEvictsOldestFirst(t *testing.T) {
+	dyn := newDynamicTable(entrySize("a") + entrySize("b"))
+	dyn.insert("a")
+	dyn.insert("b")
+	if _, ok := dyn.find("a"); !ok {
+		t.Fatal("expected \"a\" to still be present")
+	}
+	dyn.insert("c")
+	if _, ok := dyn.find("a"); ok {
+		t.Fatal("expected \"a\" to have been evicted to make room for \"c\"")
+	}
+	if _, ok := dyn.find("b"); !ok {
+		t.Fatal("expected \"b\" to still be present")
+	}
+	if _, ok := dyn.find("c"); !ok {
+		t.Fatal("expected \"c\" to be present")
+	}
+}
+
+func TestTokenizeEmptyAndSeparatorOnly(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []Pair
+	}{
+		{"", nil},
+		{"   ", []Pair{{Token: "", Separator: "   "}}},
+		{"!!!", []Pair{{Token: "", Separator: "!!!"}}},
+		{"a b", []Pair{{Token: "a", Separator: " "}, {Token: "b", Separator: ""}}},
+	}
+	for _, c := range cases {
+		got := Tokenize(c.in)
+		if len(got) != len(c.want) {
+			t.Fatalf("Tokenize(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("Tokenize(%q)[%d] = %+v, want %+v", c.in, i, got[i], c.want[i])
+			}
+		}
+	}
+}